@@ -2,31 +2,45 @@ package build
 
 import (
 	"bufio"
+	"bytes"
 	_ "embed"
+	"encoding/json"
 	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
 	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"text/template/parse"
+	"unicode"
 
+	"github.com/httprunner/funplugin"
 	"github.com/httprunner/funplugin/shared"
 	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
 )
 
 const (
 	funppy                  = `import funppy`
 	fungo                   = `"github.com/httprunner/funplugin/fungo"`
+	funjs                   = `const funplugin = require("funplugin-js")`
 	regexPythonFunctionName = `def ([a-zA-Z_]\w*)\(.*\)`
-	regexGoImports          = `import\s*\(\n([\s\S]*)\n\)`
-	regexGoImport           = `import\s*(\"[\s\S]*\")\n`
-	regexGoFunctionName     = `func ([A-Z][a-zA-Z_]\w*)\(.*\)`
-	regexGoFunctionContent  = `func [\s\S]*?\n}\n`
+	regexJsExportFunction   = `^export\s+function\s+([a-zA-Z_$][\w$]*)\s*\(`
+	regexJsModuleExport     = `^module\.exports\.([a-zA-Z_$][\w$]*)\s*=`
 )
 
 //go:embed templates/debugtalkPythonTemplate
@@ -35,70 +49,92 @@ var pyTemplate string
 //go:embed templates/debugtalkGoTemplate
 var goTemplate string
 
+//go:embed templates/debugtalkJsTemplate
+var jsTemplate string
+
+//go:embed templates/debugtalkGoEntryTemplate
+var goEntryTemplate string
+
+//go:embed templates/debugtalkPythonMultiTemplate
+var pyMultiTemplate string
+
 type TemplateContent struct {
-	Fun           string   // funplugin package
-	Regexps       *Regexps // match import/function
-	Imports       []string // python/go import
-	FromImports   []string // python from...import...
-	Functions     []string // python/go function
-	FunctionNames []string // function name set by user
+	Fun           string    // funplugin package
+	Regexps       *Regexps  // match python/js function name
+	Imports       []string  // python/go/js import
+	FromImports   []string  // python from...import...
+	Functions     []string  // python/go/js function
+	FunctionNames []string  // function name set by user
+	Refs          []FuncRef // module-qualified functions, for multi-file python plugins
+}
+
+// FuncRef names a plugin function discovered in a specific source module,
+// used to generate cross-module registration code for multi-file plugins.
+type FuncRef struct {
+	Module string
+	Name   string
 }
 
 type Regexps struct {
-	Import          *regexp.Regexp
-	Imports         *regexp.Regexp
-	FunctionName    *regexp.Regexp
-	FunctionContent *regexp.Regexp // including function define and body
+	FunctionName *regexp.Regexp
+	ModuleExport *regexp.Regexp // js module.exports.foo = ... declarations
 }
 
+// parseGoContent parses debugtalk.go with go/ast instead of brittle regexes,
+// so multi-line signatures, method receivers, grouped/aliased imports and
+// backtick struct tags don't trip up the generator.
 func (t *TemplateContent) parseGoContent(path string) error {
 	log.Info().Msg(fmt.Sprintf("start to parse %v", path))
 
-	content, err := os.ReadFile(path)
+	fset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to read file")
-		return err
+		log.Error().Err(err).Msg("failed to parse go file")
+		return errors.Wrap(err, fmt.Sprintf("parse %s failed", path))
 	}
-	originalContent := string(content)
 
-	// parse imports
-	importSlice := t.Regexps.Imports.FindAllStringSubmatch(originalContent, -1)
-	if len(importSlice) != 0 {
-		imports := strings.Replace(importSlice[0][1], "\t", "", -1)
-		for _, elem := range strings.Split(imports, "\n") {
-			t.Imports = append(t.Imports, strings.TrimSpace(elem))
-		}
-	}
-	// parse import
-	importSlice = t.Regexps.Import.FindAllStringSubmatch(originalContent, -1)
-	if len(importSlice) != 0 {
-		for _, elem := range importSlice {
-			t.Imports = append(t.Imports, strings.TrimSpace(elem[1]))
+	for _, decl := range astFile.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok != token.IMPORT {
+				continue
+			}
+			for _, spec := range d.Specs {
+				importSpec, ok := spec.(*ast.ImportSpec)
+				if !ok {
+					continue
+				}
+				var buf bytes.Buffer
+				if err := printer.Fprint(&buf, fset, importSpec); err != nil {
+					log.Error().Err(err).Msg("failed to print import spec")
+					return errors.Wrap(err, "print import spec failed")
+				}
+				t.Imports = append(t.Imports, buf.String())
+			}
+		case *ast.FuncDecl:
+			// skip methods, they can't be registered as plugin functions
+			if d.Recv != nil {
+				continue
+			}
+			name := d.Name.Name
+			if name == "main" || name == "init" || !d.Name.IsExported() {
+				continue
+			}
+
+			var buf bytes.Buffer
+			if err := printer.Fprint(&buf, fset, d); err != nil {
+				log.Error().Err(err).Msg(fmt.Sprintf("failed to print function %s", name))
+				return errors.Wrap(err, fmt.Sprintf("print function %s failed", name))
+			}
+			t.FunctionNames = append(t.FunctionNames, name)
+			t.Functions = append(t.Functions, buf.String())
 		}
 	}
+
 	// import fungo package
 	if !builtin.Contains(t.Imports, fungo) {
 		t.Imports = append(t.Imports, t.Fun)
 	}
-
-	// parse function name
-	functionNameSlice := t.Regexps.FunctionName.FindAllStringSubmatch(originalContent, -1)
-	for _, elem := range functionNameSlice {
-		name := strings.Trim(elem[1], " ")
-		if name == "main" {
-			continue
-		}
-		t.FunctionNames = append(t.FunctionNames, name)
-	}
-
-	// parse function content
-	functionContentSlice := t.Regexps.FunctionContent.FindAllStringSubmatch(originalContent, -1)
-	for _, f := range functionContentSlice {
-		if strings.Contains(f[0], "func main") {
-			continue
-		}
-		t.Functions = append(t.Functions, strings.Trim(f[0], "\n"))
-	}
 	return nil
 }
 
@@ -152,15 +188,83 @@ func (t *TemplateContent) parsePyContent(path string) error {
 	return nil
 }
 
-func (t *TemplateContent) genDebugTalk(path string, templ string) error {
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0o666)
+// parseJsContent parses debugtalk.js, collecting top-level
+// `export function foo(...)` and `module.exports.foo = ...` declarations
+// as registerable plugin functions.
+func (t *TemplateContent) parseJsContent(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to open js file")
+		return err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+
+	// record content excluding import/require lines
+	content := ""
+
+	for {
+		l, _, err := r.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		line := string(l)
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "import ") || strings.HasPrefix(trimmed, "require(") ||
+			(strings.HasPrefix(trimmed, "const ") && strings.Contains(trimmed, "require(")) {
+			t.Imports = append(t.Imports, trimmed)
+			continue
+		}
+
+		if m := t.Regexps.FunctionName.FindStringSubmatch(trimmed); m != nil {
+			t.FunctionNames = append(t.FunctionNames, m[1])
+		} else if m := t.Regexps.ModuleExport.FindStringSubmatch(trimmed); m != nil {
+			t.FunctionNames = append(t.FunctionNames, m[1])
+		}
+		content += line + "\n"
+	}
+	t.Functions = append(t.Functions, strings.Trim(content, "\n"))
+
+	// require the funplugin js shim
+	if !builtin.Contains(t.Imports, t.Fun) {
+		t.Imports = append(t.Imports, t.Fun)
+	}
+	return nil
+}
+
+// genDebugTalk renders templ (or, if templatePath is set, an external
+// template overriding it) against t. External templates get the same
+// Sprig-style FuncMap as the embedded defaults and are validated to only
+// reference whitelisted TemplateContent fields before being executed.
+func (t *TemplateContent) genDebugTalk(path string, templ string, templatePath string) error {
+	if templatePath != "" {
+		content, err := os.ReadFile(templatePath)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to read user template")
+			return errors.Wrap(err, fmt.Sprintf("read template %s failed", templatePath))
+		}
+		templ = string(content)
+	}
+
+	tmpl, err := template.New("debugtalk").Funcs(templateFuncMap()).Parse(templ)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to parse template")
+		return errors.Wrap(err, "parse template failed")
+	}
+	if err := validateTemplateFields(tmpl); err != nil {
+		log.Error().Err(err).Msg("template references invalid field")
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o666)
 	if err != nil {
 		log.Error().Err(err).Msg("open file failed")
 		return err
 	}
 	defer file.Close()
 	writer := bufio.NewWriter(file)
-	tmpl := template.Must(template.New("debugtalk").Parse(templ))
 	err = tmpl.Execute(writer, t)
 	if err != nil {
 		log.Error().Err(err).Msg("execute applies a parsed template to the specified data object failed")
@@ -175,16 +279,220 @@ func (t *TemplateContent) genDebugTalk(path string, templ string) error {
 	return err
 }
 
-// buildGo builds debugtalk.go to debugtalk.bin
-func buildGo(path string, output string) error {
+// templateContentFields whitelists the TemplateContent fields a
+// user-supplied template may reference, so a typo fails at load time
+// instead of silently rendering an empty value.
+var templateContentFields = map[string]bool{
+	"Fun": true, "Regexps": true, "Imports": true, "FromImports": true,
+	"Functions": true, "FunctionNames": true, "Refs": true,
+}
+
+// validateTemplateFields walks every parsed template's nodes and ensures
+// each {{ .Field }} reference names a whitelisted TemplateContent field.
+func validateTemplateFields(tmpl *template.Template) error {
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		if err := walkTemplateNodes(t.Tree.Root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkTemplateNodes(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Nodes {
+			if err := walkTemplateNodes(c); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return walkTemplateNodes(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if err := walkTemplateNodes(arg); err != nil {
+					return err
+				}
+			}
+		}
+	case *parse.FieldNode:
+		if len(n.Ident) > 0 && !templateContentFields[n.Ident[0]] {
+			return errors.Errorf("template references unknown TemplateContent field %q", n.Ident[0])
+		}
+	case *parse.IfNode:
+		// if doesn't change dot, so the body is still TemplateContent
+		return walkBranchNodes(n.Pipe, n.List, n.ElseList)
+	case *parse.RangeNode:
+		// range/with rebind dot to the iterated/piped value (e.g. a FuncRef
+		// inside {{range .Refs}}), so the body's fields belong to that type,
+		// not TemplateContent - only validate the pipe and the else branch
+		if err := walkTemplateNodes(n.Pipe); err != nil {
+			return err
+		}
+		return walkTemplateNodes(n.ElseList)
+	case *parse.WithNode:
+		if err := walkTemplateNodes(n.Pipe); err != nil {
+			return err
+		}
+		return walkTemplateNodes(n.ElseList)
+	}
+	return nil
+}
+
+func walkBranchNodes(pipe *parse.PipeNode, list *parse.ListNode, elseList *parse.ListNode) error {
+	if err := walkTemplateNodes(pipe); err != nil {
+		return err
+	}
+	if err := walkTemplateNodes(list); err != nil {
+		return err
+	}
+	return walkTemplateNodes(elseList)
+}
+
+// templateFuncMap provides Sprig-style helpers for user-supplied templates:
+// string helpers, list helpers, and env access.
+func templateFuncMap() template.FuncMap {
+	return template.FuncMap{
+		"camelCase": camelCase,
+		"snakeCase": snakeCase,
+		"quote":     strconv.Quote,
+		"join":      strings.Join,
+		"uniq":      uniqStrings,
+		"sortAlpha": sortAlphaStrings,
+		"env":       os.Getenv,
+		"default": func(def string, val string) string {
+			if val == "" {
+				return def
+			}
+			return val
+		},
+	}
+}
+
+func camelCase(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '_' || r == '-' || r == ' '
+	})
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		if i == 0 {
+			// lowercase only the leading rune so an already-PascalCase word
+			// with no separators (e.g. "FooBar") still becomes "fooBar"
+			// instead of being flattened to "foobar"
+			parts[i] = strings.ToLower(p[:1]) + p[1:]
+			continue
+		}
+		parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:])
+	}
+	return strings.Join(parts, "")
+}
+
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case unicode.IsUpper(r):
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case r == '-' || r == ' ':
+			b.WriteByte('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func uniqStrings(in []string) []string {
+	seen := make(map[string]bool, len(in))
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	return out
+}
+
+func sortAlphaStrings(in []string) []string {
+	out := append([]string(nil), in...)
+	sort.Strings(out)
+	return out
+}
+
+// formatGoFile formats a generated go file with go/format, failing loudly
+// instead of letting go build surface a confusing error on generated code.
+func formatGoFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	formatted, err := format.Source(content)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to format generated go file")
+		return errors.Wrap(err, fmt.Sprintf("format %s failed", path))
+	}
+	return os.WriteFile(path, formatted, 0o666)
+}
+
+// Options controls optional build behavior, such as cross-compiling
+// debugtalk.bin for multiple GOOS/GOARCH targets.
+type Options struct {
+	Targets         []string // cross-compile targets, e.g. []string{"linux/amd64", "darwin/arm64"}
+	CGOEnabled      bool     // enable cgo; disabled by default for static, portable binaries
+	LDFlags         string   // extra -ldflags appended after the builtin size/version flags
+	Trimpath        bool     // build with -trimpath for reproducible builds
+	VerifyCasesPath string   // optional path to a VerifyCase file, verified against the built artifact after build; honored by buildGo and buildPy
+	TemplatePath    string   // optional external template overriding the embedded default for the target language
+}
+
+// defaultOptions builds a single binary for the host GOOS/GOARCH, matching
+// the historical buildGo behavior.
+func defaultOptions() *Options {
+	return &Options{
+		Targets: []string{runtime.GOOS + "/" + runtime.GOARCH},
+	}
+}
+
+// ldflags assembles the -ldflags value: "-s -w" for size reduction plus an
+// "-X" stamp of the locked funplugin version, followed by any user flags.
+func (o *Options) ldflags() string {
+	flags := fmt.Sprintf("-s -w -X main.Version=%s", shared.Version)
+	if o.LDFlags != "" {
+		flags = flags + " " + o.LDFlags
+	}
+	return flags
+}
+
+// buildGo builds one or more debugtalk.go plugin sources to debugtalk.bin,
+// optionally cross-compiling one binary per target in opts.Targets so hrp
+// runners on a different OS than the plugin author can still load it.
+func buildGo(paths []string, output string, opts *Options) error {
+	if opts == nil {
+		opts = defaultOptions()
+	}
+	if len(opts.Targets) == 0 {
+		opts.Targets = defaultOptions().Targets
+	}
+
 	templateContent := &TemplateContent{
 		Fun: fungo,
-		Regexps: &Regexps{
-			Import:          regexp.MustCompile(regexGoImport),
-			Imports:         regexp.MustCompile(regexGoImports),
-			FunctionName:    regexp.MustCompile(regexGoFunctionName),
-			FunctionContent: regexp.MustCompile(regexGoFunctionContent),
-		},
 	}
 
 	// create temp dir for building
@@ -203,16 +511,52 @@ func buildGo(path string, output string) error {
 	if err := builtin.CreateFolder(pluginDir); err != nil {
 		return err
 	}
-	// parse debugtalk.go in pluginDir
-	err = templateContent.parseGoContent(path)
-	if err != nil {
-		return err
+
+	// a multi-file plugin package is copied into pluginDir verbatim so that
+	// cross-file references (helpers, consts, types) resolve during go
+	// build; the generated file then only wires up imports and registration.
+	// It's named hrp_debugtalk_gen.go rather than debugtalk.go so it can't
+	// collide with (and silently truncate) a same-named file being copied in.
+	multiFile := len(paths) > 1
+	debugtalkGoPath := filepath.Join(pluginDir, "debugtalk.go")
+	if multiFile {
+		debugtalkGoPath = filepath.Join(pluginDir, "hrp_debugtalk_gen.go")
 	}
+	for _, path := range paths {
+		if err := templateContent.parseGoContent(path); err != nil {
+			return err
+		}
+		if multiFile {
+			dest := filepath.Join(pluginDir, filepath.Base(path))
+			if dest == debugtalkGoPath {
+				return errors.Errorf("plugin source file name %q collides with the generated entry file", filepath.Base(path))
+			}
+			if err := builtin.CopyFile(path, dest); err != nil {
+				return err
+			}
+		}
+	}
+
 	// generate debugtalk.go in pluginDir
-	err = templateContent.genDebugTalk(filepath.Join(pluginDir, "debugtalk.go"), goTemplate)
+	tmpl := goTemplate
+	if multiFile {
+		// function bodies already live in the copied source files, and the
+		// entry file's body only calls fungo.Serve plus already-in-scope
+		// package-level identifiers - it needs no sibling file's imports.
+		// Merging them in verbatim would also duplicate any import shared by
+		// more than one sibling file (e.g. "fmt"), which go build rejects.
+		templateContent.Functions = nil
+		templateContent.Imports = []string{fungo}
+		tmpl = goEntryTemplate
+	}
+	err = templateContent.genDebugTalk(debugtalkGoPath, tmpl, opts.TemplatePath)
 	if err != nil {
 		return err
 	}
+	// format generated debugtalk.go before building
+	if err := formatGoFile(debugtalkGoPath); err != nil {
+		return err
+	}
 
 	// create go mod
 	if err := builtin.ExecCommandInDir(exec.Command("go", "mod", "init", "plugin"), pluginDir); err != nil {
@@ -226,46 +570,144 @@ func buildGo(path string, output string) error {
 		return err
 	}
 
+	// resolve output directory; a single native-target build may still
+	// point at an explicit output file for backward compatibility
+	outputDir := output
+	outputIsFile := output != "" && !builtin.IsFolderPathExists(output)
 	if output == "" {
-		dir, _ := os.Getwd()
-		output = filepath.Join(dir, "debugtalk.bin")
-	} else if builtin.IsFolderPathExists(output) {
-		output = filepath.Join(output, "debugtalk.bin")
+		outputDir, _ = os.Getwd()
+	} else if outputIsFile {
+		if len(opts.Targets) > 1 {
+			return errors.New("output must be a directory when building multiple cross-compile targets")
+		}
+		outputDir = filepath.Dir(output)
 	}
-	outputPath, err := filepath.Abs(output)
-	if err != nil {
-		return err
+
+	cgoEnabled := "0"
+	if opts.CGOEnabled {
+		cgoEnabled = "1"
 	}
+	ldflags := opts.ldflags()
 
-	// build plugin debugtalk.bin
-	if err := builtin.ExecCommandInDir(exec.Command("go", "build", "-o", outputPath, "debugtalk.go"), pluginDir); err != nil {
-		return err
+	for _, target := range opts.Targets {
+		goos, goarch, err := splitTarget(target)
+		if err != nil {
+			return err
+		}
+
+		var outputPath string
+		if outputIsFile {
+			outputPath = output
+		} else {
+			name := "debugtalk.bin"
+			if len(opts.Targets) > 1 || goos != runtime.GOOS || goarch != runtime.GOARCH {
+				name = fmt.Sprintf("debugtalk_%s_%s.bin", goos, goarch)
+			}
+			if goos == "windows" {
+				name = strings.TrimSuffix(name, ".bin") + ".exe"
+			}
+			outputPath = filepath.Join(outputDir, name)
+		}
+		outputPath, err = filepath.Abs(outputPath)
+		if err != nil {
+			return err
+		}
+
+		args := []string{"build", "-o", outputPath}
+		if opts.Trimpath {
+			args = append(args, "-trimpath")
+		}
+		args = append(args, "-ldflags", ldflags)
+		if multiFile {
+			// build the whole package directory so the copied sibling files
+			// (and not just the generated entry file) are included
+			args = append(args, ".")
+		} else {
+			args = append(args, "debugtalk.go")
+		}
+
+		cmd := exec.Command("go", args...)
+		cmd.Env = append(os.Environ(),
+			"GOOS="+goos, "GOARCH="+goarch, "CGO_ENABLED="+cgoEnabled)
+		if err := builtin.ExecCommandInDir(cmd, pluginDir); err != nil {
+			return err
+		}
+		log.Info().Msg(fmt.Sprintf("build %s to %s successfully", strings.Join(paths, ", "), outputPath))
+
+		// only the host-target artifact can be executed locally to verify
+		if opts.VerifyCasesPath != "" && goos == runtime.GOOS && goarch == runtime.GOARCH {
+			if err := runVerify(outputPath, opts.VerifyCasesPath); err != nil {
+				return err
+			}
+		}
 	}
-	log.Info().Msg(fmt.Sprintf("build %s to %s successfully", path, outputPath))
 	return nil
 }
 
-// buildPy completes funppy information in debugtalk.py
-func buildPy(path string, output string) error {
+// splitTarget parses a "GOOS/GOARCH" cross-compile target.
+func splitTarget(target string) (goos string, goarch string, err error) {
+	parts := strings.Split(target, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("invalid build target %q, expected GOOS/GOARCH", target)
+	}
+	return parts[0], parts[1], nil
+}
+
+// buildPy completes funppy information in debugtalk.py. When given more than
+// one path, every discovered function is additionally tracked as a FuncRef
+// so the multi-file template can re-export funppy.register(...) calls
+// module by module instead of inlining bodies from unrelated files.
+func buildPy(paths []string, output string, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
 	templateContent := &TemplateContent{
 		Fun: funppy,
 		Regexps: &Regexps{
 			FunctionName: regexp.MustCompile(regexPythonFunctionName),
 		},
 	}
-	err := templateContent.parsePyContent(path)
-	if err != nil {
-		return err
+
+	multiFile := len(paths) > 1
+	for _, path := range paths {
+		before := len(templateContent.FunctionNames)
+		if err := templateContent.parsePyContent(path); err != nil {
+			return err
+		}
+		if multiFile {
+			module := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+			for _, name := range templateContent.FunctionNames[before:] {
+				templateContent.Refs = append(templateContent.Refs, FuncRef{Module: module, Name: name})
+			}
+		}
 	}
 
-	// generate debugtalk.py
+	// resolve the directory the generated file will live in
+	outputDir := output
 	if output == "" {
-		dir, _ := os.Getwd()
-		output = filepath.Join(dir, "debugtalk_gen.py")
+		outputDir, _ = os.Getwd()
 	} else if builtin.IsFolderPathExists(output) {
-		output = filepath.Join(output, "debugtalk_gen.py")
+		outputDir = output
+	} else {
+		outputDir = filepath.Dir(output)
+	}
+	output = filepath.Join(outputDir, "debugtalk_gen.py")
+
+	// the generated entry does `from <module> import <name>` for every
+	// multi-file source, which only resolves if those modules sit next to
+	// it, so copy them into outputDir the same way buildGo copies sibling
+	// go files into pluginDir
+	if multiFile {
+		if err := copyPluginSources(paths, outputDir); err != nil {
+			return err
+		}
+	}
+
+	tmpl := pyTemplate
+	if multiFile {
+		tmpl = pyMultiTemplate
 	}
-	err = templateContent.genDebugTalk(output, pyTemplate)
+	err := templateContent.genDebugTalk(output, tmpl, opts.TemplatePath)
 	if err != nil {
 		return err
 	}
@@ -276,18 +718,317 @@ func buildPy(path string, output string) error {
 		return err
 	}
 
+	if opts.VerifyCasesPath != "" {
+		if err := runVerify(output, opts.VerifyCasesPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolvePluginFiles expands arg - a single file, a directory, or a glob -
+// into the set of plugin source files to build, along with their common
+// extension. Go plugins take precedence when a directory mixes extensions,
+// since a go plugin package may legitimately embed non-go fixtures.
+func resolvePluginFiles(arg string) (files []string, ext string, err error) {
+	if builtin.IsFolderPathExists(arg) {
+		files, err = collectPluginDir(arg)
+	} else {
+		files, err = filepath.Glob(arg)
+		if err == nil && len(files) == 0 {
+			files = []string{arg}
+		}
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if len(files) == 0 {
+		return nil, "", errors.Errorf("no plugin source files found in %s", arg)
+	}
+
+	for _, f := range files {
+		switch filepath.Ext(f) {
+		case ".go":
+			ext = ".go"
+		case ".py":
+			if ext == "" {
+				ext = ".py"
+			}
+		case ".js", ".mjs":
+			if ext == "" {
+				ext = filepath.Ext(f)
+			}
+		}
+	}
+
+	// drop files of the other languages so they never reach this plugin's parser
+	selected := make([]string, 0, len(files))
+	for _, f := range files {
+		fext := filepath.Ext(f)
+		if fext == ext || ((ext == ".js" || ext == ".mjs") && (fext == ".js" || fext == ".mjs")) {
+			selected = append(selected, f)
+		}
+	}
+	return selected, ext, nil
+}
+
+// collectPluginDir walks dir for plugin source files, honoring an optional
+// .hrpignore file in dir (one glob pattern per line, matched against paths
+// relative to dir; blank lines and `#`-prefixed comments are skipped).
+func collectPluginDir(dir string) ([]string, error) {
+	ignore, err := loadHrpIgnore(filepath.Join(dir, ".hrpignore"))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".go", ".py", ".js", ".mjs":
+		default:
+			return nil
+		}
+		if strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		for _, pattern := range ignore {
+			if matched, _ := filepath.Match(pattern, rel); matched {
+				return nil
+			}
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func loadHrpIgnore(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var patterns []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, nil
+}
+
+// copyPluginSources copies each path into dir, skipping any path that
+// already resolves to its destination (the source is already in dir).
+func copyPluginSources(paths []string, dir string) error {
+	for _, path := range paths {
+		dest := filepath.Join(dir, filepath.Base(path))
+		absSrc, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		absDest, err := filepath.Abs(dest)
+		if err != nil {
+			return err
+		}
+		if absSrc == absDest {
+			continue
+		}
+		if err := builtin.CopyFile(path, dest); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func Run(arg string, output string) (err error) {
-	ext := filepath.Ext(arg)
+// VerifyCase describes one registered function to invoke against a built
+// plugin artifact, and the result expected back.
+type VerifyCase struct {
+	Name string        `json:"name" yaml:"name"`
+	Args []interface{} `json:"args" yaml:"args"`
+	Want interface{}   `json:"want" yaml:"want"`
+}
+
+// VerifyResult records the outcome of running a single VerifyCase.
+type VerifyResult struct {
+	Name   string        `json:"name"`
+	Args   []interface{} `json:"args"`
+	Want   interface{}   `json:"want"`
+	Got    interface{}   `json:"got,omitempty"`
+	Passed bool          `json:"passed"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// VerifySummary is the machine-readable pass/fail report for a Verify run,
+// so CI can gate merges on plugin correctness.
+type VerifySummary struct {
+	OutputPath string         `json:"output_path"`
+	Total      int            `json:"total"`
+	Passed     int            `json:"passed"`
+	Failed     int            `json:"failed"`
+	Results    []VerifyResult `json:"results"`
+}
+
+// Verify spawns the built plugin artifact over the same funplugin RPC
+// transport hrp uses at runtime, then invokes each VerifyCase and diffs the
+// result against Want.
+func Verify(outputPath string, cases []VerifyCase) (*VerifySummary, error) {
+	plugin, err := funplugin.Init(outputPath)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("load plugin %s failed", outputPath))
+	}
+	defer plugin.Quit()
+
+	summary := &VerifySummary{OutputPath: outputPath, Total: len(cases)}
+	for _, c := range cases {
+		result := VerifyResult{Name: c.Name, Args: c.Args, Want: c.Want}
+
+		got, callErr := plugin.Call(c.Name, c.Args...)
+		if callErr != nil {
+			result.Error = callErr.Error()
+		} else {
+			result.Got = got
+			result.Passed = reflect.DeepEqual(normalizeForCompare(got), normalizeForCompare(c.Want))
+		}
+
+		if result.Passed {
+			summary.Passed++
+		} else {
+			summary.Failed++
+		}
+		summary.Results = append(summary.Results, result)
+	}
+
+	if summary.Failed > 0 {
+		return summary, errors.Errorf("%d/%d plugin verify cases failed", summary.Failed, summary.Total)
+	}
+	return summary, nil
+}
+
+// normalizeForCompare recursively converts numeric types and yaml.v2's
+// map[interface{}]interface{} into the shapes encoding/json would have
+// produced, so a YAML-decoded VerifyCase.Want (e.g. int(3)) compares equal
+// to the JSON-decoded result a funplugin RPC call returns (float64(3))
+// instead of spuriously failing on type alone.
+func normalizeForCompare(v interface{}) interface{} {
+	switch val := v.(type) {
+	case int:
+		return float64(val)
+	case int8:
+		return float64(val)
+	case int16:
+		return float64(val)
+	case int32:
+		return float64(val)
+	case int64:
+		return float64(val)
+	case uint:
+		return float64(val)
+	case uint8:
+		return float64(val)
+	case uint16:
+		return float64(val)
+	case uint32:
+		return float64(val)
+	case uint64:
+		return float64(val)
+	case float32:
+		return float64(val)
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, e := range val {
+			out[i] = normalizeForCompare(e)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, e := range val {
+			out[k] = normalizeForCompare(e)
+		}
+		return out
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, e := range val {
+			out[fmt.Sprintf("%v", k)] = normalizeForCompare(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// LoadVerifyCases loads VerifyCase entries from a YAML (or JSON, which is
+// valid YAML) cases file. There's no CLI subcommand in this tree to parse a
+// --verify flag from, so callers set Options.VerifyCasesPath to a cases file
+// directly; buildGo and buildPy then load it and run the verification
+// automatically.
+func LoadVerifyCases(path string) ([]VerifyCase, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cases []VerifyCase
+	if err := yaml.Unmarshal(content, &cases); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("parse verify cases %s failed", path))
+	}
+	return cases, nil
+}
+
+// runVerify loads cases from casesPath, verifies them against outputPath,
+// and emits a JSON summary to stdout regardless of outcome so CI can parse
+// pass/fail per function even when the build step fails the run.
+func runVerify(outputPath string, casesPath string) error {
+	cases, err := LoadVerifyCases(casesPath)
+	if err != nil {
+		return err
+	}
+
+	summary, verifyErr := Verify(outputPath, cases)
+	if summary != nil {
+		if encErr := json.NewEncoder(os.Stdout).Encode(summary); encErr != nil {
+			log.Error().Err(encErr).Msg("failed to emit verify summary")
+		}
+	}
+	return verifyErr
+}
+
+func Run(arg string, output string, opts *Options) (err error) {
+	files, ext, err := resolvePluginFiles(arg)
+	if err != nil {
+		log.Error().Err(err).Msg(fmt.Sprintf("failed to resolve plugin sources for %s", arg))
+		os.Exit(1)
+	}
+
 	switch ext {
 	case ".py":
-		err = buildPy(arg, output)
+		err = buildPy(files, output, opts)
 	case ".go":
-		err = buildGo(arg, output)
+		err = buildGo(files, output, opts)
+	case ".js", ".mjs":
+		// funplugin.Init - the loader hrp uses at runtime and Verify uses
+		// after build - dispatches purely on file extension and has no case
+		// for .js/.mjs (only .bin, .py and .so), so a built debugtalk_gen.js
+		// could never actually be loaded; refuse instead of shipping that.
+		err = errors.New("js/mjs plugins are not supported yet: funplugin has no .js/.mjs loader")
 	default:
-		return errors.New("type error, expected .py or .go")
+		return errors.New("type error, expected .py, .go, .js or .mjs")
 	}
 	if err != nil {
 		log.Error().Err(err).Msg(fmt.Sprintf("failed to build %s", arg))
@@ -0,0 +1,362 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestParseGoContentAST(t *testing.T) {
+	dir := t.TempDir()
+	src := `package main
+
+import (
+	"fmt"
+	_ "os"
+	myfmt "fmt"
+)
+
+// Add adds three ints together. The signature is split across lines on
+// purpose, which is exactly what used to trip up the old regex parser.
+func Add(a int, b int,
+	c int) int {
+	return a + b + c
+}
+
+type helper struct{}
+
+func (h *helper) Skip() int {
+	return 0
+}
+
+func unexported() {}
+
+func init() {}
+
+func main() {
+	fmt.Println(myfmt.Sprintf("%d", Add(1, 2, 3)))
+}
+`
+	path := filepath.Join(dir, "debugtalk.go")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tc := &TemplateContent{Fun: fungo}
+	if err := tc.parseGoContent(path); err != nil {
+		t.Fatalf("parseGoContent failed: %v", err)
+	}
+
+	if len(tc.FunctionNames) != 1 || tc.FunctionNames[0] != "Add" {
+		t.Fatalf("expected only exported func Add, got %v", tc.FunctionNames)
+	}
+	if len(tc.Functions) != 1 || !strings.Contains(tc.Functions[0], "func Add") {
+		t.Fatalf("expected Add function body to be captured, got %v", tc.Functions)
+	}
+	if !containsSubstring(tc.Imports, `"fmt"`) {
+		t.Fatalf("expected fmt import to be collected, got %v", tc.Imports)
+	}
+	if !containsSubstring(tc.Imports, `myfmt "fmt"`) {
+		t.Fatalf("expected aliased import to be preserved, got %v", tc.Imports)
+	}
+	if !containsSubstring(tc.Imports, fungo) {
+		t.Fatalf("expected fungo import to be injected, got %v", tc.Imports)
+	}
+}
+
+func containsSubstring(values []string, want string) bool {
+	for _, v := range values {
+		if strings.Contains(v, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSplitTarget(t *testing.T) {
+	goos, goarch, err := splitTarget("linux/amd64")
+	if err != nil || goos != "linux" || goarch != "amd64" {
+		t.Fatalf("splitTarget(linux/amd64) = %q, %q, %v", goos, goarch, err)
+	}
+
+	for _, bad := range []string{"linux", "linux/amd64/extra", "/amd64", "linux/"} {
+		if _, _, err := splitTarget(bad); err == nil {
+			t.Fatalf("splitTarget(%q) expected error, got nil", bad)
+		}
+	}
+}
+
+func TestOptionsLdflags(t *testing.T) {
+	opts := &Options{}
+	flags := opts.ldflags()
+	if !strings.Contains(flags, "-s -w") {
+		t.Fatalf("expected size-reduction flags in %q", flags)
+	}
+	if !strings.Contains(flags, "-X main.Version=") {
+		t.Fatalf("expected version stamp in %q", flags)
+	}
+
+	opts.LDFlags = "-X main.Custom=1"
+	flags = opts.ldflags()
+	if !strings.HasSuffix(flags, "-X main.Custom=1") {
+		t.Fatalf("expected user ldflags appended, got %q", flags)
+	}
+}
+
+func TestParseJsContent(t *testing.T) {
+	dir := t.TempDir()
+	src := `const axios = require("axios")
+
+export function Add(a, b) {
+	return a + b
+}
+
+function helper() {
+	return 0
+}
+
+module.exports.Sub = function (a, b) {
+	return a - b
+}
+`
+	path := filepath.Join(dir, "debugtalk.js")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tc := &TemplateContent{
+		Fun: funjs,
+		Regexps: &Regexps{
+			FunctionName: regexp.MustCompile(regexJsExportFunction),
+			ModuleExport: regexp.MustCompile(regexJsModuleExport),
+		},
+	}
+	if err := tc.parseJsContent(path); err != nil {
+		t.Fatalf("parseJsContent failed: %v", err)
+	}
+
+	if !containsSubstring(tc.FunctionNames, "Add") || !containsSubstring(tc.FunctionNames, "Sub") {
+		t.Fatalf("expected Add and Sub to be discovered, got %v", tc.FunctionNames)
+	}
+	if containsSubstring(tc.FunctionNames, "helper") {
+		t.Fatalf("did not expect unexported helper to be registered, got %v", tc.FunctionNames)
+	}
+	if !containsSubstring(tc.Imports, "axios") {
+		t.Fatalf("expected require(\"axios\") to be collected, got %v", tc.Imports)
+	}
+	if !containsSubstring(tc.Imports, funjs) {
+		t.Fatalf("expected funplugin js shim to be injected, got %v", tc.Imports)
+	}
+}
+
+func TestCollectPluginDirHonorsHrpIgnore(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"debugtalk.go", "helper.go", "helper_test.go", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("package main\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ignore := ".hrpignore"
+	if err := os.WriteFile(filepath.Join(dir, ignore), []byte("# comment\nhelper.go\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := collectPluginDir(dir)
+	if err != nil {
+		t.Fatalf("collectPluginDir failed: %v", err)
+	}
+	if containsSubstring(files, "helper.go") {
+		t.Fatalf("expected helper.go to be ignored via .hrpignore, got %v", files)
+	}
+	if containsSubstring(files, "helper_test.go") {
+		t.Fatalf("expected _test.go files to be excluded, got %v", files)
+	}
+	if containsSubstring(files, "notes.txt") {
+		t.Fatalf("expected non plugin-source files to be excluded, got %v", files)
+	}
+	if !containsSubstring(files, "debugtalk.go") {
+		t.Fatalf("expected debugtalk.go to be collected, got %v", files)
+	}
+}
+
+func TestResolvePluginFilesDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"debugtalk.go", "helper.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("package main\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, ext, err := resolvePluginFiles(dir)
+	if err != nil {
+		t.Fatalf("resolvePluginFiles failed: %v", err)
+	}
+	if ext != ".go" {
+		t.Fatalf("expected .go extension, got %q", ext)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %v", files)
+	}
+}
+
+func TestLoadVerifyCases(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cases.yaml")
+	content := `- name: Add
+  args: [1, 2]
+  want: 3
+- name: Concat
+  args: ["a", "b"]
+  want: "ab"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases, err := LoadVerifyCases(path)
+	if err != nil {
+		t.Fatalf("LoadVerifyCases failed: %v", err)
+	}
+	if len(cases) != 2 {
+		t.Fatalf("expected 2 cases, got %v", cases)
+	}
+	if cases[0].Name != "Add" || len(cases[0].Args) != 2 {
+		t.Fatalf("unexpected first case: %+v", cases[0])
+	}
+	if cases[1].Name != "Concat" {
+		t.Fatalf("unexpected second case: %+v", cases[1])
+	}
+}
+
+func TestNormalizeForCompareNumericTypeMismatch(t *testing.T) {
+	// want as decoded by yaml.v2, got as decoded by encoding/json - the two
+	// decoders disagree on concrete numeric type for the same value
+	want := int(3)
+	got := float64(3)
+	if !reflect.DeepEqual(normalizeForCompare(got), normalizeForCompare(want)) {
+		t.Fatalf("expected int(3) and float64(3) to normalize equal, got %#v vs %#v",
+			normalizeForCompare(got), normalizeForCompare(want))
+	}
+}
+
+func TestNormalizeForCompareNestedMap(t *testing.T) {
+	// yaml.v2 decodes nested maps as map[interface{}]interface{}, json always
+	// decodes them as map[string]interface{}
+	want := map[interface{}]interface{}{"count": int(2)}
+	got := map[string]interface{}{"count": float64(2)}
+	if !reflect.DeepEqual(normalizeForCompare(got), normalizeForCompare(want)) {
+		t.Fatalf("expected nested maps to normalize equal, got %#v vs %#v",
+			normalizeForCompare(got), normalizeForCompare(want))
+	}
+}
+
+func TestValidateTemplateFieldsRangeDotContext(t *testing.T) {
+	tmpl, err := template.New("debugtalk").Funcs(templateFuncMap()).Parse(
+		`{{range .Refs}}from {{.Module}} import {{.Name}}{{end}}`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if err := validateTemplateFields(tmpl); err != nil {
+		t.Fatalf("expected range body fields to be exempt from the TemplateContent whitelist, got: %v", err)
+	}
+}
+
+func TestValidateTemplateFieldsRejectsUnknownField(t *testing.T) {
+	tmpl, err := template.New("debugtalk").Funcs(templateFuncMap()).Parse(`{{.Bogus}}`)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if err := validateTemplateFields(tmpl); err == nil {
+		t.Fatal("expected unknown top-level field to be rejected")
+	}
+}
+
+func TestCamelCase(t *testing.T) {
+	cases := map[string]string{
+		"foo_bar": "fooBar",
+		"foo-bar": "fooBar",
+		"foo bar": "fooBar",
+		"FooBar":  "fooBar",
+		"foo":     "foo",
+	}
+	for in, want := range cases {
+		if got := camelCase(in); got != want {
+			t.Errorf("camelCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	cases := map[string]string{
+		"fooBar":  "foo_bar",
+		"FooBar":  "foo_bar",
+		"foo-bar": "foo_bar",
+		"foo bar": "foo_bar",
+		"foo":     "foo",
+	}
+	for in, want := range cases {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestUniqStrings(t *testing.T) {
+	got := uniqStrings([]string{"a", "b", "a", "c", "b"})
+	want := []string{"a", "b", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("uniqStrings = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("uniqStrings = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSortAlphaStrings(t *testing.T) {
+	in := []string{"c", "a", "b"}
+	got := sortAlphaStrings(in)
+	want := []string{"a", "b", "c"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("sortAlphaStrings(%v) = %v, want %v", in, got, want)
+		}
+	}
+	if in[0] != "c" {
+		t.Fatalf("sortAlphaStrings mutated its input: %v", in)
+	}
+}
+
+func TestCopyPluginSourcesCopiesIntoOutputDir(t *testing.T) {
+	srcDir := t.TempDir()
+	outDir := t.TempDir()
+	path := filepath.Join(srcDir, "helper.py")
+	if err := os.WriteFile(path, []byte("def helper():\n    return 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := copyPluginSources([]string{path}, outDir); err != nil {
+		t.Fatalf("copyPluginSources failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "helper.py")); err != nil {
+		t.Fatalf("expected helper.py to be copied into outDir: %v", err)
+	}
+}
+
+func TestCopyPluginSourcesSkipsSameDir(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "helper.py")
+	if err := os.WriteFile(path, []byte("def helper():\n    return 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// source already lives in dir - must not error trying to copy it onto itself
+	if err := copyPluginSources([]string{path}, dir); err != nil {
+		t.Fatalf("copyPluginSources failed: %v", err)
+	}
+}
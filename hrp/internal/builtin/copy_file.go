@@ -0,0 +1,38 @@
+package builtin
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// CopyFile copies src to dst, preserving src's file mode, overwriting dst if
+// it already exists.
+func CopyFile(src string, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return errors.Wrap(err, "stat source file failed")
+	}
+
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return errors.Wrap(err, "open source file failed")
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return errors.Wrap(err, "create destination file failed")
+	}
+	defer dstFile.Close()
+
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		return errors.Wrap(err, "copy file content failed")
+	}
+	// OpenFile only applies the mode when it creates dst
+	if err := os.Chmod(dst, info.Mode()); err != nil {
+		return errors.Wrap(err, "set destination file mode failed")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package builtin
+
+import (
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+// EnsureNodeEnv checks that node and npm are available on PATH and installs
+// pkg (the funplugin JS shim) into the current directory's node_modules,
+// mirroring EnsurePython3Venv for the JS plugin backend.
+func EnsureNodeEnv(pkg string) (string, error) {
+	nodePath, err := exec.LookPath("node")
+	if err != nil {
+		return "", errors.Wrap(err, "node not installed, please install node first")
+	}
+	if _, err := exec.LookPath("npm"); err != nil {
+		return "", errors.Wrap(err, "npm not installed, please install npm first")
+	}
+
+	installCmd := exec.Command("npm", "install", pkg)
+	if output, err := installCmd.CombinedOutput(); err != nil {
+		return "", errors.Wrap(err, "install "+pkg+" failed: "+string(output))
+	}
+
+	return nodePath, nil
+}